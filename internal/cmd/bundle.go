@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/turbot/go-kit/helpers"
+	"github.com/turbot/pipe-fittings/cmdconfig"
+	"github.com/turbot/pipe-fittings/constants"
+	"github.com/turbot/pipe-fittings/contexthelpers"
+	"github.com/turbot/pipe-fittings/error_helpers"
+	"github.com/turbot/pipe-fittings/modconfig"
+	"github.com/turbot/pipe-fittings/utils"
+	localcmdconfig "github.com/turbot/powerpipe/internal/cmdconfig"
+	localconstants "github.com/turbot/powerpipe/internal/constants"
+	"github.com/turbot/powerpipe/internal/controlinit"
+	"github.com/turbot/powerpipe/internal/db_client"
+)
+
+// maxPersistedSummaries caps the rolling on-disk buffer of check run summaries,
+// trimmed on every write so the buffer does not grow without bound
+const maxPersistedSummaries = 50
+
+// bundleCmd is a top-level command which captures a redacted diagnostics tarball
+// that can be attached to an issue instead of hand-collecting config, mod state,
+// plugin versions and error output.
+func bundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:              "bundle",
+		TraverseChildren: true,
+		Args:             cobra.NoArgs,
+		Run:              runBundleCmd,
+		Short:            "Capture a redacted diagnostics bundle for support/triage",
+		Long: `Capture a redacted diagnostics bundle for support/triage.
+
+Assembles config, mod state, connection/plugin metadata and recent control run
+summaries into a single tar.gz artifact that can be attached to an issue.`,
+	}
+
+	cmdconfig.OnCmd(cmd).
+		AddModLocationFlag().
+		AddStringFlag(localconstants.ArgOutputFile, "powerpipe-support-bundle.tar.gz", "The file to write the diagnostics bundle to").
+		AddBoolFlag(localconstants.ArgStdout, false, "Write the diagnostics bundle to stdout instead of a file").
+		AddIntFlag(localconstants.ArgIncludeRecentResults, 5, "The number of recent control run summaries to include").
+		AddBoolFlag(localconstants.ArgRedact, true, "Redact connection strings, tokens and workspace handles from the bundle")
+
+	return cmd
+}
+
+func runBundleCmd(cmd *cobra.Command, _ []string) {
+	utils.LogTime("runBundleCmd start")
+	ctx, cancel := context.WithCancel(cmd.Context())
+	contexthelpers.StartCancelHandler(cancel)
+
+	var panicStack string
+	defer func() {
+		utils.LogTime("runBundleCmd end")
+		if r := recover(); r != nil {
+			panicStack = helpers.ToError(r).Error()
+			error_helpers.ShowError(ctx, helpers.ToError(r))
+			exitCode = constants.ExitCodeUnknownErrorPanic
+		}
+	}()
+
+	redact := viper.GetBool(localconstants.ArgRedact)
+
+	b := newBundleBuilder(redact)
+	b.addConfig()
+
+	// reuse the same init path as `check`, targeting "all", purely to obtain a
+	// resolved workspace and connected DbClient - no execution tree is built
+	initData := controlinit.NewInitData[modconfig.Benchmark](ctx, []string{"all"})
+	if initData.Result.Error != nil {
+		error_helpers.ShowError(ctx, initData.Result.Error)
+	} else {
+		defer initData.Cleanup(ctx)
+		b.addModGraph(initData.Workspace)
+		b.addConnectionDiagnostics(ctx, initData.Client)
+	}
+
+	b.addRecentResults(viper.GetInt(localconstants.ArgIncludeRecentResults))
+	if panicStack == "" {
+		panicStack = readLastPanic()
+	}
+	if panicStack != "" {
+		b.addPanicStack(panicStack)
+	}
+
+	var out io.Writer
+	if viper.GetBool(localconstants.ArgStdout) {
+		out = os.Stdout
+	} else {
+		outputFile := viper.GetString(localconstants.ArgOutputFile)
+		f, err := os.Create(outputFile)
+		if err != nil {
+			error_helpers.ShowError(ctx, fmt.Errorf("could not create output file: %w", err))
+			exitCode = constants.ExitCodeInsufficientOrWrongInputs
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := b.write(out); err != nil {
+		error_helpers.ShowError(ctx, fmt.Errorf("could not write diagnostics bundle: %w", err))
+		exitCode = constants.ExitCodeUnknownErrorPanic
+		return
+	}
+}
+
+// bundleEntry is a single named file added to the diagnostics tarball
+type bundleEntry struct {
+	name string
+	data []byte
+}
+
+// bundleBuilder accumulates bundleEntry values and renders them as a tar.gz
+type bundleBuilder struct {
+	redact  bool
+	entries []bundleEntry
+}
+
+func newBundleBuilder(redact bool) *bundleBuilder {
+	return &bundleBuilder{redact: redact}
+}
+
+func (b *bundleBuilder) add(name string, data []byte) {
+	if b.redact {
+		data = []byte(redactSecrets(string(data)))
+	}
+	b.entries = append(b.entries, bundleEntry{name: name, data: data})
+}
+
+func (b *bundleBuilder) addConfig() {
+	config := localcmdconfig.DisplayConfig()
+	b.add("config.txt", []byte(config))
+}
+
+// modGraphVariable is a single resolved mod variable, with its value redacted
+// unless --redact=false was passed
+type modGraphVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// modGraphNode describes a mod, its resolved variable values, and the mods it
+// requires, so the full dependency graph can be reconstructed from the root node
+type modGraphNode struct {
+	ShortName    string             `json:"short_name"`
+	Variables    []modGraphVariable `json:"variables"`
+	Dependencies []modGraphNode     `json:"dependencies,omitempty"`
+}
+
+// addModGraph writes the resolved workspace mod graph, redacting variable values
+// unless --redact=false was passed. Variable values are redacted at the source
+// here (rather than relying on the generic text redactor in add()) since they
+// rarely match a recognizable secret pattern.
+func (b *bundleBuilder) addModGraph(workspace *modconfig.Workspace) {
+	node := b.buildModGraphNode(workspace.Mod)
+
+	data, err := json.MarshalIndent(node, "", "  ")
+	if err != nil {
+		b.add("mod_graph_error.txt", []byte(err.Error()))
+		return
+	}
+	// variable values are already redacted above, so bypass add()'s generic
+	// text redactor to avoid mangling the JSON
+	b.entries = append(b.entries, bundleEntry{name: "mod_graph.json", data: data})
+}
+
+// buildModGraphNode builds a modGraphNode for mod, recursing into its required
+// mods so the full dependency graph is captured, not just the root mod
+func (b *bundleBuilder) buildModGraphNode(mod *modconfig.Mod) modGraphNode {
+	node := modGraphNode{ShortName: mod.ShortName}
+	for name, variable := range mod.ResourceMaps.Variables {
+		value := fmt.Sprintf("%v", variable.Value)
+		if b.redact {
+			value = "<redacted>"
+		}
+		node.Variables = append(node.Variables, modGraphVariable{Name: name, Value: value})
+	}
+	sort.Slice(node.Variables, func(i, j int) bool { return node.Variables[i].Name < node.Variables[j].Name })
+
+	if mod.Require != nil {
+		for _, dep := range mod.Require.Mods {
+			depMod, ok := mod.ResourceMaps.ModMap[dep.Name]
+			if !ok {
+				continue
+			}
+			node.Dependencies = append(node.Dependencies, b.buildModGraphNode(depMod))
+		}
+	}
+	sort.Slice(node.Dependencies, func(i, j int) bool { return node.Dependencies[i].ShortName < node.Dependencies[j].ShortName })
+
+	return node
+}
+
+func (b *bundleBuilder) addConnectionDiagnostics(ctx context.Context, client db_client.DbClient) {
+	diagnostics, err := db_client.GetDiagnostics(ctx, client)
+	if err != nil {
+		b.add("diagnostics_error.txt", []byte(err.Error()))
+		return
+	}
+	data, err := json.MarshalIndent(diagnostics, "", "  ")
+	if err != nil {
+		b.add("diagnostics_error.txt", []byte(err.Error()))
+		return
+	}
+	b.add("diagnostics.json", data)
+}
+
+func (b *bundleBuilder) addRecentResults(n int) {
+	summaries := recentSummaries(n)
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return
+	}
+	b.add("recent_results.json", data)
+}
+
+func (b *bundleBuilder) addPanicStack(stack string) {
+	b.add("panic.txt", []byte(stack))
+}
+
+func (b *bundleBuilder) write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, e := range b.entries {
+		hdr := &tar.Header{
+			Name:    e.name,
+			Mode:    0600,
+			Size:    int64(len(e.data)),
+			ModTime: time.Unix(0, 0),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// redactSecretPatterns matches common secret-bearing env var names
+var redactSecretPatterns = regexp.MustCompile(`(?i)(.*(?:_TOKEN|_KEY|_SECRET)\s*=\s*)(\S+)`)
+
+// redactConnectionString matches postgres/mysql style connection strings
+var redactConnectionString = regexp.MustCompile(`(?i)([a-z][a-z0-9+.-]*://)[^@\s]+(@)`)
+
+// redactPipesHandle matches Turbot Pipes workspace handles, e.g. acme/dev
+var redactPipesHandle = regexp.MustCompile(`(?i)(workspace[-_]?handle\s*[:=]\s*)(\S+)`)
+
+// redactSecrets scrubs connection strings, common env var secrets and Turbot Pipes
+// workspace handles from diagnostic text before it is written to the bundle
+func redactSecrets(s string) string {
+	s = redactSecretPatterns.ReplaceAllString(s, "${1}<redacted>")
+	s = redactConnectionString.ReplaceAllString(s, "${1}<redacted>${2}")
+	s = redactPipesHandle.ReplaceAllString(s, "${1}<redacted>")
+	return s
+}
+
+// recentSummaries reads up to n control run summaries from the rolling on-disk buffer
+func recentSummaries(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	buf, err := readSummaryBuffer()
+	if err != nil {
+		return nil
+	}
+	if len(buf) > n {
+		buf = buf[len(buf)-n:]
+	}
+	return buf
+}
+
+// summaryBufferPath is the rolling on-disk buffer of recent execution tree summaries,
+// appended to by persistSummary whenever a check command completes
+func summaryBufferPath() string {
+	return localcmdconfig.GetDataDir() + "/check_summaries.log"
+}
+
+func readSummaryBuffer() ([]string, error) {
+	data, err := os.ReadFile(summaryBufferPath())
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	return lines, nil
+}
+
+// persistSummary appends a single execution tree summary line to the rolling buffer,
+// called from runCheckCmd once a tree has finished executing. The buffer is
+// trimmed to maxPersistedSummaries entries on every write so it does not grow
+// without bound.
+func persistSummary(summary string) error {
+	existing, err := readSummaryBuffer()
+	if err != nil {
+		existing = nil
+	}
+	lines := append(existing, summary)
+	if len(lines) > maxPersistedSummaries {
+		lines = lines[len(lines)-maxPersistedSummaries:]
+	}
+	return os.WriteFile(summaryBufferPath(), []byte(strings.Join(lines, "\n")+"\n"), 0600)
+}
+
+// panicFilePath is where the most recent panic captured by runCheckCmd's recover() is
+// written, so a later `powerpipe bundle` invocation can include it
+func panicFilePath() string {
+	return localcmdconfig.GetDataDir() + "/last_panic.txt"
+}
+
+// persistPanic records a panic captured by runCheckCmd's recover(), with the
+// stack of the panicking goroutine, overwriting any previously recorded panic
+func persistPanic(err error) error {
+	return os.WriteFile(panicFilePath(), []byte(err.Error()+"\n\n"+string(debug.Stack())), 0600)
+}
+
+// readLastPanic returns the most recently persisted panic stack, if any
+func readLastPanic() string {
+	data, err := os.ReadFile(panicFilePath())
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
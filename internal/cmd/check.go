@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -46,11 +47,11 @@ func checkCmd[T controlinit.CheckTarget]() *cobra.Command {
 		AddBoolFlag(constants.ArgHeader, true, "Include column headers for csv and table output").
 		AddBoolFlag(constants.ArgHelp, false, "Help for run command", cmdconfig.FlagOptions.WithShortHand("h")).
 		AddStringFlag(constants.ArgSeparator, ",", "Separator string for csv output").
-		AddStringFlag(constants.ArgOutput, constants.OutputFormatText, "Output format: brief, csv, html, json, md, text, snapshot or none").
+		AddStringFlag(constants.ArgOutput, constants.OutputFormatText, "Output format: brief, csv, html, json, junit, md, text, snapshot or none").
 		AddBoolFlag(constants.ArgTiming, false, "Turn on the timer which reports run time").
 		AddStringSliceFlag(constants.ArgSearchPath, nil, "Set a custom search_path (comma-separated)").
 		AddStringSliceFlag(constants.ArgSearchPathPrefix, nil, "Set a prefix to the current search path (comma-separated)").
-		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, md, nunit3, sps (snapshot), asff").
+		AddStringSliceFlag(constants.ArgExport, nil, "Export output to file, supported formats: csv, html, json, junit, md, nunit3, sarif, sps (snapshot), asff").
 		AddBoolFlag(constants.ArgProgress, true, "Display control execution progress").
 		AddStringSliceFlag(constants.ArgVarFile, nil, "Specify an .ppvar file containing variable values").
 		// NOTE: use StringArrayFlag for ArgVariable, not StringSliceFlag
@@ -75,7 +76,10 @@ func checkCmd[T controlinit.CheckTarget]() *cobra.Command {
 			AddStringFlag(constants.ArgWhere, "", "SQL 'where' clause, or named query, used to filter controls (cannot be used with '--tag')").
 			AddBoolFlag(constants.ArgDryRun, false, "Show which controls will be run without running them").
 			AddStringSliceFlag(constants.ArgTag, nil, "Filter controls based on their tag values ('--tag key=value')").
-			AddIntFlag(constants.ArgMaxParallel, constants.DefaultMaxConnections, "The maximum number of concurrent database connections to open")
+			AddIntFlag(constants.ArgMaxParallel, constants.DefaultMaxConnections, "The maximum number of concurrent database connections to open").
+			AddStringFlag(localconstants.ArgCompareTo, "", "Compare results against a prior baseline file as written by '--update-baseline' (local path or Turbot Pipes URL) and report a diff").
+			AddStringFlag(localconstants.ArgFailOn, "any_regression", "When used with '--compare-to', fail on: new_alarms, new_errors or any_regression").
+			AddBoolFlag(localconstants.ArgUpdateBaseline, false, "When used with '--compare-to', persist this run as the next baseline snapshot")
 	}
 
 	return cmd
@@ -108,7 +112,10 @@ func runCheckCmd[T controlinit.CheckTarget](cmd *cobra.Command, args []string) {
 	defer func() {
 		utils.LogTime("runCheckCmd end")
 		if r := recover(); r != nil {
-			error_helpers.ShowError(ctx, helpers.ToError(r))
+			err := helpers.ToError(r)
+			// best-effort: persist the panic stack so it can be picked up by `powerpipe bundle`
+			_ = persistPanic(err)
+			error_helpers.ShowError(ctx, err)
 			exitCode = constants.ExitCodeUnknownErrorPanic
 		}
 	}()
@@ -172,6 +179,11 @@ func runCheckCmd[T controlinit.CheckTarget](cmd *cobra.Command, args []string) {
 	totalAlarms = namedTree.tree.Root.Summary.Status.Alarm
 	totalErrors = namedTree.tree.Root.Summary.Status.Error
 
+	// best-effort: keep a rolling buffer of recent run summaries for `powerpipe bundle`
+	if summary, err := json.Marshal(namedTree.tree.Root.Summary); err == nil {
+		_ = persistSummary(string(summary))
+	}
+
 	err = publishSnapshot(ctx, namedTree.tree, viper.GetBool(constants.ArgShare), viper.GetBool(constants.ArgSnapshot))
 	if err != nil {
 		error_helpers.ShowError(ctx, err)
@@ -186,6 +198,59 @@ func runCheckCmd[T controlinit.CheckTarget](cmd *cobra.Command, args []string) {
 		error_helpers.ShowError(ctx, err)
 		totalErrors++
 	}
+
+	if compareTo := viper.GetString(localconstants.ArgCompareTo); compareTo != "" {
+		shouldFail, err := compareToBaseline(ctx, namedTree.tree, compareTo)
+		if err != nil {
+			error_helpers.ShowError(ctx, err)
+			totalErrors++
+		} else {
+			// with --compare-to, --fail-on alone decides whether control results fail the run
+			totalAlarms -= namedTree.tree.Root.Summary.Status.Alarm
+			totalErrors -= namedTree.tree.Root.Summary.Status.Error
+			if shouldFail {
+				totalAlarms++
+			}
+		}
+	}
+}
+
+// compareToBaseline loads the snapshot at compareTo, diffs it against tree, prints
+// the diff and reports whether the run should fail the build per --fail-on.
+// If --update-baseline is set and compareTo is a local path, tree is persisted as
+// the next baseline.
+func compareToBaseline(ctx context.Context, tree *controlexecute.ExecutionTree, compareTo string) (bool, error) {
+	baseline, err := controlexecute.LoadBaseline(compareTo)
+	if err != nil {
+		return false, sperr.WrapWithMessage(err, "could not load baseline for comparison")
+	}
+
+	diff := controlexecute.NewExecutionTreeDiff(tree, baseline)
+
+	asJSON := viper.GetString(constants.ArgOutput) == constants.OutputFormatJSON
+	reader, err := controldisplay.NewDiffFormatter(asJSON).FormatDiff(ctx, diff)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(os.Stdout, reader); err != nil {
+		return false, err
+	}
+
+	isURL := strings.HasPrefix(compareTo, "http://") || strings.HasPrefix(compareTo, "https://")
+	if viper.GetBool(localconstants.ArgUpdateBaseline) && !isURL {
+		if err := controlexecute.WriteBaseline(tree, compareTo); err != nil {
+			return false, sperr.WrapWithMessage(err, "could not update baseline")
+		}
+	}
+
+	switch viper.GetString(localconstants.ArgFailOn) {
+	case localconstants.FailOnNewAlarms:
+		return diff.HasNewAlarms(), nil
+	case localconstants.FailOnNewErrors:
+		return diff.HasNewErrors(), nil
+	default:
+		return diff.HasRegressions(), nil
+	}
 }
 
 // exportExecutionTree relies on the fact that the given tree is already executed
@@ -197,9 +262,29 @@ func exportExecutionTree(ctx context.Context, namedTree *namedExecutionTree, ini
 		return ctx.Err()
 	}
 
-	exportMsg, err := initData.ExportManager.DoExport(ctx, namedTree.name, namedTree.tree, exportArgs)
-	if err != nil {
-		return err
+	// formats registered via controldisplay.RegisterFormatter (sarif, junit) are not
+	// known to the legacy ExportManager - handle those directly here and pass
+	// everything else through to ExportManager as before
+	var legacyFormats []string
+	var exportMsg []string
+	for _, format := range exportArgs {
+		msg, err := exportRegisteredFormat(ctx, namedTree, format)
+		if err != nil {
+			return err
+		}
+		if msg == "" {
+			legacyFormats = append(legacyFormats, format)
+			continue
+		}
+		exportMsg = append(exportMsg, msg)
+	}
+
+	if len(legacyFormats) > 0 {
+		msgs, err := initData.ExportManager.DoExport(ctx, namedTree.name, namedTree.tree, legacyFormats)
+		if err != nil {
+			return err
+		}
+		exportMsg = append(exportMsg, msgs...)
 	}
 
 	// print the location where the file is exported if progress=true
@@ -210,6 +295,35 @@ func exportExecutionTree(ctx context.Context, namedTree *namedExecutionTree, ini
 	return nil
 }
 
+// exportRegisteredFormat writes namedTree.tree using the controldisplay.Formatter
+// registered under format, if any, and returns the message to report for it.
+// Returns an empty message (and no error) if format is not a registered formatter,
+// so the caller can fall back to the legacy ExportManager.
+func exportRegisteredFormat(ctx context.Context, namedTree *namedExecutionTree, format string) (string, error) {
+	formatter, err := controldisplay.GetFormatter(format)
+	if err != nil {
+		return "", nil
+	}
+
+	reader, err := formatter.Format(ctx, namedTree.tree)
+	if err != nil {
+		return "", fmt.Errorf("could not format %s export: %w", format, err)
+	}
+
+	outputPath := fmt.Sprintf("%s%s", namedTree.name, formatter.FileExtension())
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("could not create export file %s: %w", outputPath, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, reader); err != nil {
+		return "", fmt.Errorf("could not write export file %s: %w", outputPath, err)
+	}
+
+	return fmt.Sprintf("Exported %s", outputPath), nil
+}
+
 // executeTree executes and displays the (table) results of an execution
 func executeTree(ctx context.Context, tree *controlexecute.ExecutionTree, initData *controlinit.InitData) error {
 	// create a context with check status hooks
@@ -219,7 +333,14 @@ func executeTree(ctx context.Context, tree *controlexecute.ExecutionTree, initDa
 		return err
 	}
 
-	err = displayControlResults(checkCtx, tree, initData.OutputFormatter)
+	// initData.OutputFormatter does not know about formats registered via
+	// controldisplay.RegisterFormatter (e.g. junit) - use those directly when requested
+	formatter := initData.OutputFormatter
+	if registered, err := controldisplay.GetFormatter(viper.GetString(constants.ArgOutput)); err == nil {
+		formatter = registered
+	}
+
+	err = displayControlResults(checkCtx, tree, formatter)
 	if err != nil {
 		return err
 	}
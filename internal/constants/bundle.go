@@ -0,0 +1,9 @@
+package constants
+
+// flags for `powerpipe bundle`
+const (
+	ArgOutputFile           = "output-file"
+	ArgStdout               = "stdout"
+	ArgIncludeRecentResults = "include-recent-results"
+	ArgRedact               = "redact"
+)
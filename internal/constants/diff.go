@@ -0,0 +1,15 @@
+package constants
+
+// flags for benchmark diff/regression mode (`--compare-to`)
+const (
+	ArgCompareTo      = "compare-to"
+	ArgFailOn         = "fail-on"
+	ArgUpdateBaseline = "update-baseline"
+)
+
+// values accepted by --fail-on
+const (
+	FailOnNewAlarms     = "new_alarms"
+	FailOnNewErrors     = "new_errors"
+	FailOnAnyRegression = "any_regression"
+)
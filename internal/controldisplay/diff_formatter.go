@@ -0,0 +1,102 @@
+package controldisplay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/turbot/powerpipe/internal/controlexecute"
+)
+
+// DiffFormatter renders an ExecutionTreeDiff, either as colored text grouped by
+// benchmark or as JSON for machine consumption.
+type DiffFormatter struct {
+	FormatterBase
+	// JSON selects JSON output instead of the default colored text rendering
+	JSON bool
+}
+
+func NewDiffFormatter(asJSON bool) *DiffFormatter {
+	return &DiffFormatter{JSON: asJSON}
+}
+
+func (f *DiffFormatter) Name() string {
+	if f.JSON {
+		return "diff-json"
+	}
+	return "diff"
+}
+
+func (f *DiffFormatter) FileExtension() string {
+	if f.JSON {
+		return ".json"
+	}
+	return ".txt"
+}
+
+// FormatDiff renders diff as either text or JSON. Diff formatting operates on an
+// ExecutionTreeDiff rather than a plain ExecutionTree, so it is exposed as a
+// dedicated method rather than satisfying the Formatter interface directly.
+func (f *DiffFormatter) FormatDiff(_ context.Context, diff *controlexecute.ExecutionTreeDiff) (io.Reader, error) {
+	if f.JSON {
+		return f.formatJSON(diff)
+	}
+	return f.formatText(diff)
+}
+
+func (f *DiffFormatter) formatJSON(diff *controlexecute.ExecutionTreeDiff) (io.Reader, error) {
+	data, err := json.MarshalIndent(diff.Diffs, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal diff: %w", err)
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (f *DiffFormatter) formatText(diff *controlexecute.ExecutionTreeDiff) (io.Reader, error) {
+	var buf bytes.Buffer
+
+	byBenchmark := map[string][]controlexecute.ControlDiff{}
+	var order []string
+	for _, d := range diff.Diffs {
+		if d.Transition == controlexecute.ControlTransitionUnchanged {
+			continue
+		}
+		if _, ok := byBenchmark[d.BenchmarkPath]; !ok {
+			order = append(order, d.BenchmarkPath)
+		}
+		byBenchmark[d.BenchmarkPath] = append(byBenchmark[d.BenchmarkPath], d)
+	}
+
+	for _, benchmark := range order {
+		fmt.Fprintf(&buf, "%s\n", benchmark)
+		for _, d := range byBenchmark[benchmark] {
+			fmt.Fprintf(&buf, "  %s %s\n", diffSign(d.Transition), diffLine(d))
+		}
+	}
+
+	if len(order) == 0 {
+		buf.WriteString("no control result changes since baseline\n")
+	}
+
+	return &buf, nil
+}
+
+func diffSign(t controlexecute.ControlTransition) string {
+	switch t {
+	case controlexecute.ControlTransitionNewlyAlarming, controlexecute.ControlTransitionNewlyError:
+		return color.RedString("+")
+	case controlexecute.ControlTransitionNewlyOk:
+		return color.GreenString("-")
+	case controlexecute.ControlTransitionDisappeared:
+		return color.YellowString("~")
+	default:
+		return " "
+	}
+}
+
+func diffLine(d controlexecute.ControlDiff) string {
+	return fmt.Sprintf("%s: %s -> %s (%s)", d.ControlName, d.PreviousStatus, d.CurrentStatus, d.Transition)
+}
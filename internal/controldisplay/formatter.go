@@ -2,6 +2,8 @@ package controldisplay
 
 import (
 	"context"
+	"fmt"
+
 	"github.com/turbot/powerpipe/internal/controlexecute"
 	"io"
 )
@@ -18,3 +20,25 @@ type FormatterBase struct{}
 func (*FormatterBase) Alias() string {
 	return ""
 }
+
+// formatterRegistry maps an --export/--output format name to a constructor for
+// the Formatter that handles it. Formatters register themselves via
+// RegisterFormatter, typically from an init() in their own file.
+var formatterRegistry = map[string]func() Formatter{}
+
+// RegisterFormatter adds a named formatter constructor to the registry consulted
+// by the check command when resolving --export/--output format names, ahead of
+// the export manager, which does not know these formats.
+func RegisterFormatter(name string, constructor func() Formatter) {
+	formatterRegistry[name] = constructor
+}
+
+// GetFormatter resolves a format name (as passed to --export or --output) to a
+// Formatter instance.
+func GetFormatter(name string) (Formatter, error) {
+	constructor, ok := formatterRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported export format %q", name)
+	}
+	return constructor(), nil
+}
@@ -0,0 +1,143 @@
+package controldisplay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/turbot/powerpipe/internal/controlexecute"
+)
+
+// JUnitFormatter implements Formatter, rendering an ExecutionTree as JUnit XML -
+// the format natively understood by Jenkins, GitLab CI, CircleCI, Buildkite and
+// virtually every GitHub Actions test-reporter plugin.
+type JUnitFormatter struct {
+	FormatterBase
+}
+
+func NewJUnitFormatter() *JUnitFormatter {
+	return &JUnitFormatter{}
+}
+
+func init() {
+	RegisterFormatter("junit", func() Formatter { return NewJUnitFormatter() })
+}
+
+func (f *JUnitFormatter) Name() string {
+	return "junit"
+}
+
+func (f *JUnitFormatter) FileExtension() string {
+	return ".xml"
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string            `xml:"name,attr"`
+	Tests      int               `xml:"tests,attr"`
+	Failures   int               `xml:"failures,attr"`
+	Skipped    int               `xml:"skipped,attr"`
+	Properties []junitProperty   `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase   `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct{}
+
+func (f *JUnitFormatter) Format(_ context.Context, tree *controlexecute.ExecutionTree) (io.Reader, error) {
+	suites := junitTestSuites{}
+
+	groups := map[string]*junitTestSuite{}
+	var order []string
+
+	addControlRun := func(path string, controlRun *controlexecute.ControlRun) {
+		suite, ok := groups[path]
+		if !ok {
+			suite = &junitTestSuite{Name: path, Properties: []junitProperty{{Name: "path", Value: path}}}
+			groups[path] = suite
+			order = append(order, path)
+		}
+		suite.Tests++
+
+		testCase := junitTestCase{
+			ClassName: path,
+			Name:      controlRun.Control.GetTitle(),
+			Time:      fmt.Sprintf("%.3f", controlRun.Duration.Seconds()),
+		}
+
+		switch controlRun.Status {
+		case "alarm", "error":
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Type:    controlRun.Status,
+				Message: controlRun.Reason,
+				Body:    controlRun.Reason,
+			}
+		case "skip":
+			suite.Skipped++
+			testCase.Skipped = &junitSkipped{}
+		}
+
+		if rows, err := json.Marshal(controlRun); err == nil {
+			testCase.SystemOut = string(rows)
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	var walkGroup func(group *controlexecute.ResultGroup, path []string)
+	walkGroup = func(group *controlexecute.ResultGroup, path []string) {
+		groupPath := path
+		if name := group.GroupItem.GetUnqualifiedName(); name != "" && name != "benchmark.root" {
+			groupPath = append(append([]string{}, path...), name)
+		}
+		for _, controlRun := range group.ControlRuns {
+			addControlRun(strings.Join(groupPath, "."), controlRun)
+		}
+		for _, child := range group.Groups {
+			walkGroup(child, groupPath)
+		}
+	}
+	walkGroup(tree.Root, nil)
+
+	for _, path := range order {
+		suites.Suites = append(suites.Suites, *groups[path])
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return nil, fmt.Errorf("could not marshal junit xml: %w", err)
+	}
+
+	return &buf, nil
+}
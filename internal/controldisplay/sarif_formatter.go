@@ -0,0 +1,275 @@
+package controldisplay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/turbot/powerpipe/internal/controlexecute"
+)
+
+// sarifVersion is the SARIF schema version produced by SarifFormatter.
+const sarifVersion = "2.1.0"
+
+const sarifSchema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+
+// SarifFormatter implements Formatter, rendering an ExecutionTree as a SARIF 2.1.0 log
+// so that results can be consumed by GitHub Code Scanning, GitLab and Azure DevOps.
+type SarifFormatter struct {
+	FormatterBase
+}
+
+func NewSarifFormatter() *SarifFormatter {
+	return &SarifFormatter{}
+}
+
+func init() {
+	RegisterFormatter("sarif", func() Formatter { return NewSarifFormatter() })
+}
+
+func (f *SarifFormatter) Name() string {
+	return "sarif"
+}
+
+func (f *SarifFormatter) FileExtension() string {
+	return ".sarif"
+}
+
+// sarifLog is the top level SARIF log object
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool      `json:"tool"`
+	Results    []sarifResult  `json:"results"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string                  `json:"name"`
+	InformationURI string                  `json:"informationUri,omitempty"`
+	Rules          []sarifReportDescriptor `json:"rules"`
+}
+
+type sarifReportDescriptor struct {
+	ID                   string                    `json:"id"`
+	ShortDescription     sarifMessage              `json:"shortDescription,omitempty"`
+	FullDescription      sarifMessage              `json:"fullDescription,omitempty"`
+	Help                 sarifMessage              `json:"help,omitempty"`
+	Properties           map[string]any            `json:"properties,omitempty"`
+	DefaultConfiguration *sarifReportConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifReportConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Kind      string          `json:"kind"`
+	Level     string          `json:"level,omitempty"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion            `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine,omitempty"`
+}
+
+func (f *SarifFormatter) Format(_ context.Context, tree *controlexecute.ExecutionTree) (io.Reader, error) {
+	var runs []sarifRun
+
+	// tree.Root is a synthetic "benchmark.root" wrapper; its Groups are the root
+	// benchmarks the user actually asked to run - emit one sarifRun per root benchmark
+	for _, rootGroup := range tree.Root.Groups {
+		runs = append(runs, sarifRunForGroup(rootGroup))
+	}
+
+	// a bare `powerpipe control run` has no enclosing benchmark group, so its
+	// control runs sit directly on tree.Root - represent those as their own run
+	if len(tree.Root.ControlRuns) > 0 {
+		runs = append(runs, sarifRunForGroup(tree.Root))
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    runs,
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal sarif log: %w", err)
+	}
+	return bytes.NewReader(data), nil
+}
+
+// sarifRunForGroup renders group and all its nested child groups as a single
+// sarifRun, named for the root benchmark it was called with.
+func sarifRunForGroup(group *controlexecute.ResultGroup) sarifRun {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "powerpipe",
+				InformationURI: "https://powerpipe.io",
+			},
+		},
+	}
+	if name := group.GroupItem.GetUnqualifiedName(); name != "" && name != "benchmark.root" {
+		run.Properties = map[string]any{"benchmark": name}
+	}
+
+	rules := map[string]sarifReportDescriptor{}
+
+	var walkGroup func(group *controlexecute.ResultGroup)
+	walkGroup = func(group *controlexecute.ResultGroup) {
+		for _, controlRun := range group.ControlRuns {
+			control := controlRun.Control
+			ruleID := control.GetUnqualifiedName()
+
+			if _, ok := rules[ruleID]; !ok {
+				rules[ruleID] = sarifControlRule(ruleID, controlRun)
+			}
+
+			run.Results = append(run.Results, sarifControlResult(ruleID, controlRun))
+		}
+		for _, child := range group.Groups {
+			walkGroup(child)
+		}
+	}
+	walkGroup(group)
+
+	for _, ruleID := range sortedRuleIDs(rules) {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rules[ruleID])
+	}
+
+	return run
+}
+
+func sarifControlRule(ruleID string, controlRun *controlexecute.ControlRun) sarifReportDescriptor {
+	control := controlRun.Control
+	descriptor := sarifReportDescriptor{
+		ID:                ruleID,
+		ShortDescription:  sarifMessage{Text: control.GetTitle()},
+		FullDescription:   sarifMessage{Text: control.GetDescription()},
+		Help:              sarifMessage{Text: control.GetDescription()},
+		DefaultConfiguration: &sarifReportConfiguration{
+			Level: sarifLevelForSeverity(control.GetSeverity()),
+		},
+	}
+	if tags := control.GetTags(); len(tags) > 0 {
+		descriptor.Properties = map[string]any{"tags": tags}
+	}
+	return descriptor
+}
+
+func sarifControlResult(ruleID string, controlRun *controlexecute.ControlRun) sarifResult {
+	result := sarifResult{
+		RuleID:  ruleID,
+		Kind:    sarifKindForStatus(controlRun.Status),
+		Level:   sarifLevelForStatus(controlRun.Status),
+		Message: sarifMessage{Text: controlRun.Reason},
+	}
+
+	if len(controlRun.Dimensions) > 0 {
+		dimensions := make(map[string]any, len(controlRun.Dimensions))
+		for _, d := range controlRun.Dimensions {
+			dimensions[d.Key] = d.Value
+		}
+		result.Properties = map[string]any{"dimensions": dimensions}
+	}
+
+	if sourceRange := controlRun.Control.GetSourceRange(); sourceRange != nil {
+		result.Locations = []sarifLocation{
+			{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: sourceRange.Filename},
+					Region:           sarifRegion{StartLine: sourceRange.StartLineNumber},
+				},
+			},
+		}
+	}
+
+	return result
+}
+
+// sarifKindForStatus maps a control run status to the SARIF "kind" property
+func sarifKindForStatus(status string) string {
+	switch status {
+	case "ok":
+		return "pass"
+	case "alarm", "error":
+		return "fail"
+	case "skip":
+		return "notApplicable"
+	default:
+		return "informational"
+	}
+}
+
+// sarifLevelForStatus maps a control run status to a SARIF result level
+func sarifLevelForStatus(status string) string {
+	switch status {
+	case "alarm", "error":
+		return "error"
+	case "skip":
+		return "note"
+	default:
+		return "none"
+	}
+}
+
+// sarifLevelForSeverity maps a control's severity to the SARIF rule default level
+func sarifLevelForSeverity(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	case "low", "info":
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+func sortedRuleIDs(rules map[string]sarifReportDescriptor) []string {
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	// keep output deterministic for diffable SARIF files
+	for i := 1; i < len(ids); i++ {
+		for j := i; j > 0 && ids[j-1] > ids[j]; j-- {
+			ids[j-1], ids[j] = ids[j], ids[j-1]
+		}
+	}
+	return ids
+}
@@ -0,0 +1,86 @@
+package controlexecute
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// LoadBaseline loads a prior baseline and flattens its control runs into a
+// lookup keyed by control resource name, for use with NewExecutionTreeDiff.
+// The baseline is read as JSON holding a top-level control_runs list, the layout
+// WriteBaseline writes; a file without that list loads as an empty baseline.
+// snapshotPathOrURL may be a local file path or a Turbot Pipes workspace URL.
+func LoadBaseline(snapshotPathOrURL string) (map[string]BaselineControlRun, error) {
+	raw, err := readBaselineSnapshot(snapshotPathOrURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not load baseline snapshot %q: %w", snapshotPathOrURL, err)
+	}
+
+	var snapshot struct {
+		ControlRuns []BaselineControlRun `json:"control_runs"`
+	}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, fmt.Errorf("could not parse baseline snapshot %q: %w", snapshotPathOrURL, err)
+	}
+
+	baseline := make(map[string]BaselineControlRun, len(snapshot.ControlRuns))
+	for _, c := range snapshot.ControlRuns {
+		baseline[c.ControlName] = c
+	}
+	return baseline, nil
+}
+
+func readBaselineSnapshot(snapshotPathOrURL string) ([]byte, error) {
+	if strings.HasPrefix(snapshotPathOrURL, "http://") || strings.HasPrefix(snapshotPathOrURL, "https://") {
+		return fetchPipesSnapshot(snapshotPathOrURL)
+	}
+	return os.ReadFile(snapshotPathOrURL)
+}
+
+// fetchPipesSnapshot downloads a snapshot published to a Turbot Pipes workspace
+func fetchPipesSnapshot(url string) ([]byte, error) {
+	resp, err := http.Get(url) //nolint:gosec,noctx // url is user supplied via --compare-to, same trust boundary as other CLI args
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching snapshot", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// WriteBaseline persists the current tree as the next baseline, for `--update-baseline`.
+func WriteBaseline(tree *ExecutionTree, path string) error {
+	runs := flattenControlRuns(tree)
+	data, err := json.MarshalIndent(struct {
+		ControlRuns []BaselineControlRun `json:"control_runs"`
+	}{ControlRuns: runs}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func flattenControlRuns(tree *ExecutionTree) []BaselineControlRun {
+	var runs []BaselineControlRun
+	var walk func(group *ResultGroup)
+	walk = func(group *ResultGroup) {
+		for _, controlRun := range group.ControlRuns {
+			runs = append(runs, BaselineControlRun{
+				ControlName: controlRun.Control.GetUnqualifiedName(),
+				Status:      controlRun.Status,
+				Reason:      controlRun.Reason,
+			})
+		}
+		for _, child := range group.Groups {
+			walk(child)
+		}
+	}
+	walk(tree.Root)
+	return runs
+}
@@ -0,0 +1,164 @@
+package controlexecute
+
+// ControlTransition describes how a single control's status moved between a
+// baseline run and the current run.
+type ControlTransition string
+
+const (
+	ControlTransitionNewlyAlarming  ControlTransition = "newly_alarming"
+	ControlTransitionNewlyOk        ControlTransition = "newly_ok"
+	ControlTransitionNewlyError     ControlTransition = "newly_error"
+	ControlTransitionUnchangedAlarm ControlTransition = "unchanged_alarm"
+	ControlTransitionDisappeared    ControlTransition = "disappeared"
+	ControlTransitionUnchanged      ControlTransition = "unchanged"
+)
+
+// BaselineControlRun is the subset of a ControlRun's state needed to diff against a
+// later run, as loaded from a prior .sps snapshot.
+type BaselineControlRun struct {
+	ControlName string
+	Status      string
+	Reason      string
+}
+
+// ControlDiff is a single control's transition between a baseline run and the
+// current run.
+type ControlDiff struct {
+	ControlName    string
+	BenchmarkPath  string
+	Transition     ControlTransition
+	PreviousStatus string
+	CurrentStatus  string
+	Reason         string
+}
+
+// ExecutionTreeDiff holds the per-control transitions between a baseline run and a
+// freshly executed ExecutionTree.
+type ExecutionTreeDiff struct {
+	Current  *ExecutionTree
+	Baseline map[string]BaselineControlRun
+	Diffs    []ControlDiff
+}
+
+// NewExecutionTreeDiff walks current, comparing each control run against the
+// matching entry (by control resource name) in baseline.
+func NewExecutionTreeDiff(current *ExecutionTree, baseline map[string]BaselineControlRun) *ExecutionTreeDiff {
+	d := &ExecutionTreeDiff{
+		Current:  current,
+		Baseline: baseline,
+	}
+
+	seen := make(map[string]bool, len(baseline))
+
+	var walk func(group *ResultGroup, path []string)
+	walk = func(group *ResultGroup, path []string) {
+		groupPath := path
+		if name := group.GroupItem.GetUnqualifiedName(); name != "" && name != "benchmark.root" {
+			groupPath = append(append([]string{}, path...), name)
+		}
+		for _, controlRun := range group.ControlRuns {
+			controlName := controlRun.Control.GetUnqualifiedName()
+			seen[controlName] = true
+			d.Diffs = append(d.Diffs, diffControlRun(controlName, groupPath, controlRun, baseline[controlName]))
+		}
+		for _, child := range group.Groups {
+			walk(child, groupPath)
+		}
+	}
+	walk(current.Root, nil)
+
+	// any baseline control not visited above no longer exists in the current run
+	for name, prior := range baseline {
+		if seen[name] {
+			continue
+		}
+		d.Diffs = append(d.Diffs, ControlDiff{
+			ControlName:    name,
+			Transition:     ControlTransitionDisappeared,
+			PreviousStatus: prior.Status,
+		})
+	}
+
+	return d
+}
+
+func diffControlRun(controlName string, path []string, current *ControlRun, prior BaselineControlRun) ControlDiff {
+	diff := ControlDiff{
+		ControlName:    controlName,
+		CurrentStatus:  current.Status,
+		PreviousStatus: prior.Status,
+		Reason:         current.Reason,
+		Transition:     classifyTransition(prior.Status, current.Status),
+	}
+	if len(path) > 0 {
+		diff.BenchmarkPath = joinPath(path)
+	}
+
+	return diff
+}
+
+// classifyTransition decides how a control moved between a baseline run (priorStatus,
+// empty if the control did not appear in the baseline) and the current run
+// (currentStatus). Split out from diffControlRun so the decision table can be
+// unit tested without constructing a ControlRun.
+func classifyTransition(priorStatus, currentStatus string) ControlTransition {
+	switch {
+	case priorStatus == "" && isAlarmingStatus(currentStatus):
+		return ControlTransitionNewlyAlarming
+	case priorStatus == "":
+		return ControlTransitionUnchanged
+	case isAlarmingStatus(priorStatus) && currentStatus == "ok":
+		return ControlTransitionNewlyOk
+	case priorStatus != "error" && currentStatus == "error":
+		return ControlTransitionNewlyError
+	case currentStatus == "alarm" && priorStatus != "alarm":
+		return ControlTransitionNewlyAlarming
+	case isAlarmingStatus(priorStatus) && isAlarmingStatus(currentStatus):
+		return ControlTransitionUnchangedAlarm
+	default:
+		return ControlTransitionUnchanged
+	}
+}
+
+func isAlarmingStatus(status string) bool {
+	return status == "alarm" || status == "error"
+}
+
+func joinPath(path []string) string {
+	out := path[0]
+	for _, p := range path[1:] {
+		out += "." + p
+	}
+	return out
+}
+
+// HasRegressions returns true if the diff contains any newly alarming or newly
+// erroring control.
+func (d *ExecutionTreeDiff) HasRegressions() bool {
+	for _, diff := range d.Diffs {
+		if diff.Transition == ControlTransitionNewlyAlarming || diff.Transition == ControlTransitionNewlyError {
+			return true
+		}
+	}
+	return false
+}
+
+// HasNewAlarms returns true if the diff contains any newly alarming control.
+func (d *ExecutionTreeDiff) HasNewAlarms() bool {
+	for _, diff := range d.Diffs {
+		if diff.Transition == ControlTransitionNewlyAlarming {
+			return true
+		}
+	}
+	return false
+}
+
+// HasNewErrors returns true if the diff contains any newly erroring control.
+func (d *ExecutionTreeDiff) HasNewErrors() bool {
+	for _, diff := range d.Diffs {
+		if diff.Transition == ControlTransitionNewlyError {
+			return true
+		}
+	}
+	return false
+}
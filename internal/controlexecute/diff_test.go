@@ -0,0 +1,69 @@
+package controlexecute
+
+import "testing"
+
+func TestClassifyTransition(t *testing.T) {
+	tests := []struct {
+		name      string
+		prior     string
+		current   string
+		wantTrans ControlTransition
+	}{
+		{"new control alarming", "", "alarm", ControlTransitionNewlyAlarming},
+		{"new control ok", "", "ok", ControlTransitionUnchanged},
+		{"alarm resolved to ok", "alarm", "ok", ControlTransitionNewlyOk},
+		{"error resolved to ok", "error", "ok", ControlTransitionNewlyOk},
+		{"ok became error", "ok", "error", ControlTransitionNewlyError},
+		{"ok became alarm", "ok", "alarm", ControlTransitionNewlyAlarming},
+		{"alarm became error", "alarm", "error", ControlTransitionNewlyError},
+		{"still alarming", "alarm", "alarm", ControlTransitionUnchangedAlarm},
+		{"still erroring counts as unchanged alarm", "error", "error", ControlTransitionUnchangedAlarm},
+		{"still ok", "ok", "ok", ControlTransitionUnchanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyTransition(tt.prior, tt.current)
+			if got != tt.wantTrans {
+				t.Errorf("classifyTransition(%q, %q) = %q, want %q", tt.prior, tt.current, got, tt.wantTrans)
+			}
+		})
+	}
+}
+
+func TestExecutionTreeDiffHasHelpers(t *testing.T) {
+	diff := &ExecutionTreeDiff{
+		Diffs: []ControlDiff{
+			{ControlName: "control.a", Transition: ControlTransitionUnchanged},
+			{ControlName: "control.b", Transition: ControlTransitionNewlyOk},
+		},
+	}
+	if diff.HasRegressions() {
+		t.Error("HasRegressions() = true, want false for a diff with no alarming/erroring transitions")
+	}
+	if diff.HasNewAlarms() {
+		t.Error("HasNewAlarms() = true, want false")
+	}
+	if diff.HasNewErrors() {
+		t.Error("HasNewErrors() = true, want false")
+	}
+
+	diff.Diffs = append(diff.Diffs, ControlDiff{ControlName: "control.c", Transition: ControlTransitionNewlyAlarming})
+	if !diff.HasRegressions() {
+		t.Error("HasRegressions() = false, want true once a newly_alarming control is present")
+	}
+	if !diff.HasNewAlarms() {
+		t.Error("HasNewAlarms() = false, want true once a newly_alarming control is present")
+	}
+	if diff.HasNewErrors() {
+		t.Error("HasNewErrors() = true, want false - no newly_error control present")
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	got := joinPath([]string{"benchmark.cis_v300", "section_1", "control.one"})
+	want := "benchmark.cis_v300.section_1.control.one"
+	if got != want {
+		t.Errorf("joinPath() = %q, want %q", got, want)
+	}
+}
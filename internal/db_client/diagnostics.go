@@ -0,0 +1,33 @@
+package db_client
+
+import (
+	"context"
+	"fmt"
+)
+
+// Diagnostics is a point-in-time snapshot of connection and plugin metadata,
+// captured for inclusion in a `powerpipe bundle` support bundle.
+type Diagnostics struct {
+	SearchPath       []string          `json:"search_path"`
+	ConnectionString string            `json:"connection_string"`
+	PluginVersions   map[string]string `json:"plugin_versions"`
+}
+
+// diagnosticsCapable is implemented by a DbClient backend that can report
+// connection/plugin diagnostics. It is kept separate from the DbClient
+// interface itself (declared elsewhere in this package) so that not every
+// backend is forced to implement it.
+type diagnosticsCapable interface {
+	Diagnostics(ctx context.Context) (*Diagnostics, error)
+}
+
+// GetDiagnostics builds a Diagnostics snapshot for the given client, for inclusion
+// in a `powerpipe bundle` support bundle. Returns an error if client does not
+// support diagnostics.
+func GetDiagnostics(ctx context.Context, client DbClient) (*Diagnostics, error) {
+	dc, ok := client.(diagnosticsCapable)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support connection diagnostics", client)
+	}
+	return dc.Diagnostics(ctx)
+}